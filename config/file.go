@@ -0,0 +1,257 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A File is a parsed configuration file exposing typed accessors, so
+// callers don't have to parse ints, bools, durations and lists from
+// map[string]string by hand.
+type File struct {
+	sections map[string]*Section
+}
+
+// ReadFile parses the INI data in r and returns it as a File.
+func ReadFile(r io.Reader) (*File, error) {
+	raw, err := NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{sections: make(map[string]*Section, len(raw))}
+	for name, options := range raw {
+		f.sections[name] = newSection(name, options)
+	}
+	return f, nil
+}
+
+// Section returns the named section. If name does not exist, Section
+// returns an empty, detached Section that is not added to f: merely
+// inspecting the result, unlike NewSection, never causes a later
+// HasSection(name) to report true.
+func (f *File) Section(name string) *Section {
+	if s, ok := f.sections[name]; ok {
+		return s
+	}
+	return newSection(name, nil)
+}
+
+// HasSection reports whether name exists, without creating it.
+func (f *File) HasSection(name string) bool {
+	_, ok := f.sections[name]
+	return ok
+}
+
+// NewSection adds an empty section with the given name to f, overwriting
+// any existing section of that name, and returns it.
+func (f *File) NewSection(name string) *Section {
+	s := newSection(name, nil)
+	f.sections[name] = s
+	return s
+}
+
+// Sections returns every section, sorted by name.
+func (f *File) Sections() []*Section {
+	names := make([]string, 0, len(f.sections))
+	for name := range f.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sections := make([]*Section, len(names))
+	for i, name := range names {
+		sections[i] = f.sections[name]
+	}
+	return sections
+}
+
+// SectionsByPrefix returns, sorted by name, every section whose name has
+// the given prefix. This is useful for reading flattened subsections, e.g.
+// SectionsByPrefix("remote.") after ReadAll flattened [remote "origin"] to
+// "remote.origin".
+func (f *File) SectionsByPrefix(prefix string) []*Section {
+	var matched []*Section
+	for _, s := range f.Sections() {
+		if strings.HasPrefix(s.Name(), prefix) {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+// A Section is a named group of Keys.
+type Section struct {
+	name string
+	keys map[string]*Key
+}
+
+func newSection(name string, options map[string]string) *Section {
+	s := &Section{name: name, keys: make(map[string]*Key, len(options))}
+
+	names := make([]string, 0, len(options))
+	for key := range options {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	for _, key := range names {
+		s.NewKey(key, options[key])
+	}
+	return s
+}
+
+// Name returns the section's name.
+func (s *Section) Name() string {
+	return s.name
+}
+
+// Key returns the named key. If name does not exist, Key returns an empty,
+// detached Key that is not added to s: merely inspecting the result,
+// unlike NewKey, never causes a later HasKey(name) to report true.
+func (s *Section) Key(name string) *Key {
+	if k, ok := s.keys[name]; ok {
+		return k
+	}
+	return &Key{name: name}
+}
+
+// HasKey reports whether name exists in the section, without creating it.
+func (s *Section) HasKey(name string) bool {
+	_, ok := s.keys[name]
+	return ok
+}
+
+// Keys returns every key in the section, sorted by name.
+func (s *Section) Keys() []*Key {
+	names := make([]string, 0, len(s.keys))
+	for name := range s.keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keys := make([]*Key, len(names))
+	for i, name := range names {
+		keys[i] = s.keys[name]
+	}
+	return keys
+}
+
+// NewKey adds a key with the given name and value to the section,
+// overwriting any existing key of that name, and returns it.
+func (s *Section) NewKey(name, value string) *Key {
+	k := &Key{name: name, value: value}
+	s.keys[name] = k
+	return k
+}
+
+// A Key is a single option's name and value, with typed accessors on top of
+// its raw string value.
+type Key struct {
+	name  string
+	value string
+	err   error
+}
+
+// Name returns the key's name.
+func (k *Key) Name() string {
+	return k.name
+}
+
+// String returns the key's raw, unconverted value.
+func (k *Key) String() string {
+	return k.value
+}
+
+// MustString returns the key's value, or def if the value is empty.
+func (k *Key) MustString(def string) string {
+	if k.value == "" {
+		return def
+	}
+	return k.value
+}
+
+// Int converts the key's value to an int. The error, if any, is also
+// retained and can be retrieved later with LastError.
+func (k *Key) Int() (int, error) {
+	n, err := strconv.Atoi(k.value)
+	k.err = err
+	return n, err
+}
+
+// MustInt is like Int but returns def instead of an error.
+func (k *Key) MustInt(def int) int {
+	n, err := k.Int()
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Int64 converts the key's value to an int64.
+func (k *Key) Int64() (int64, error) {
+	n, err := strconv.ParseInt(k.value, 10, 64)
+	k.err = err
+	return n, err
+}
+
+// Uint converts the key's value to a uint.
+func (k *Key) Uint() (uint, error) {
+	n, err := strconv.ParseUint(k.value, 10, strconv.IntSize)
+	k.err = err
+	return uint(n), err
+}
+
+// Float64 converts the key's value to a float64.
+func (k *Key) Float64() (float64, error) {
+	n, err := strconv.ParseFloat(k.value, 64)
+	k.err = err
+	return n, err
+}
+
+// Bool converts the key's value to a bool, consulting BoolStrings.
+func (k *Key) Bool() (bool, error) {
+	b, ok := BoolStrings[strings.ToLower(k.value)]
+	if !ok {
+		k.err = fmt.Errorf("config: invalid bool value %q", k.value)
+		return false, k.err
+	}
+	k.err = nil
+	return b, nil
+}
+
+// Duration converts the key's value to a time.Duration.
+func (k *Key) Duration() (time.Duration, error) {
+	d, err := time.ParseDuration(k.value)
+	k.err = err
+	return d, err
+}
+
+// Time converts the key's value to a time.Time using layout.
+func (k *Key) Time(layout string) (time.Time, error) {
+	t, err := time.Parse(layout, k.value)
+	k.err = err
+	return t, err
+}
+
+// Strings splits the key's value on delim, trimming whitespace from each
+// element. It returns nil if the value is empty.
+func (k *Key) Strings(delim string) []string {
+	if k.value == "" {
+		return nil
+	}
+	parts := strings.Split(k.value, delim)
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+// LastError returns the error, if any, from the most recent typed
+// conversion attempted on this key.
+func (k *Key) LastError() error {
+	return k.err
+}
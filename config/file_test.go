@@ -0,0 +1,152 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadFile(t *testing.T) {
+	const data = `
+[server]
+host = localhost
+port = 8080
+enabled = yes
+timeout = 1500ms
+tags = a, b, c
+ratio = 0.5
+`
+	f, err := ReadFile(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !f.HasSection("server") {
+		t.Fatal(`expected HasSection("server") to be true`)
+	}
+	s := f.Section("server")
+
+	if got := s.Key("host").String(); got != "localhost" {
+		t.Errorf("host = %q, want %q", got, "localhost")
+	}
+	if got := s.Key("port").MustInt(0); got != 8080 {
+		t.Errorf("port = %d, want %d", got, 8080)
+	}
+	if got, err := s.Key("enabled").Bool(); err != nil || !got {
+		t.Errorf("enabled = %v, %v, want true, nil", got, err)
+	}
+	if got, err := s.Key("timeout").Duration(); err != nil || got != 1500*time.Millisecond {
+		t.Errorf("timeout = %v, %v, want %v, nil", got, err, 1500*time.Millisecond)
+	}
+	if got := s.Key("tags").Strings(","); !equalStrings(got, []string{"a", "b", "c"}) {
+		t.Errorf("tags = %v, want %v", got, []string{"a", "b", "c"})
+	}
+	if got, err := s.Key("ratio").Float64(); err != nil || got != 0.5 {
+		t.Errorf("ratio = %v, %v, want 0.5, nil", got, err)
+	}
+}
+
+func TestKeyMustStringDefault(t *testing.T) {
+	f, err := ReadFile(strings.NewReader("[server]\nhost =\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Section("server").Key("host").MustString("fallback"); got != "fallback" {
+		t.Errorf("MustString = %q, want %q", got, "fallback")
+	}
+}
+
+func TestKeyLastError(t *testing.T) {
+	f, err := ReadFile(strings.NewReader("[server]\nport = not-a-number\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := f.Section("server").Key("port")
+	if got := key.MustInt(42); got != 42 {
+		t.Errorf("MustInt = %d, want %d", got, 42)
+	}
+	if key.LastError() == nil {
+		t.Error("expected LastError to be non-nil after a failed conversion")
+	}
+}
+
+func TestSectionKeyDoesNotCreateMissingKey(t *testing.T) {
+	f, err := ReadFile(strings.NewReader("[server]\nhost = localhost\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := f.Section("server")
+	if s.HasKey("missing") {
+		t.Fatal("expected HasKey to be false before access")
+	}
+	if got := s.Key("missing").String(); got != "" {
+		t.Errorf("missing key String() = %q, want empty", got)
+	}
+	if s.HasKey("missing") {
+		t.Error("expected HasKey to remain false after a read-only Key access")
+	}
+}
+
+func TestFileSectionDoesNotCreateMissingSection(t *testing.T) {
+	f, err := ReadFile(strings.NewReader("[server]\nhost = localhost\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.HasSection("missing") {
+		t.Fatal("expected HasSection to be false before access")
+	}
+	if got := f.Section("missing").Name(); got != "missing" {
+		t.Errorf("Section(%q).Name() = %q, want %q", "missing", got, "missing")
+	}
+	if f.HasSection("missing") {
+		t.Error("expected HasSection to remain false after a read-only Section access")
+	}
+}
+
+func TestFileNewSectionPersists(t *testing.T) {
+	f, err := ReadFile(strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.NewSection("extra").NewKey("k", "v")
+	if !f.HasSection("extra") {
+		t.Fatal("expected HasSection to be true after NewSection")
+	}
+	if got := f.Section("extra").Key("k").String(); got != "v" {
+		t.Errorf("extra.k = %q, want %q", got, "v")
+	}
+}
+
+func TestFileSectionsByPrefix(t *testing.T) {
+	const data = `
+[remote.origin]
+url = a
+[remote.upstream]
+url = b
+[core]
+bare = false
+`
+	f, err := ReadFile(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	remotes := f.SectionsByPrefix("remote.")
+	if len(remotes) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(remotes))
+	}
+	if remotes[0].Name() != "remote.origin" || remotes[1].Name() != "remote.upstream" {
+		t.Errorf("unexpected section order: %v", remotes)
+	}
+}
+
+func TestSectionNewKey(t *testing.T) {
+	f, err := ReadFile(strings.NewReader("[server]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := f.Section("server")
+	s.NewKey("host", "example.com")
+	if got := s.Key("host").String(); got != "example.com" {
+		t.Errorf("host = %q, want %q", got, "example.com")
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// defaultMaxInterpolationDepth is the recursion limit applied when
+// SetMaxInterpolationDepth has not been called.
+const defaultMaxInterpolationDepth = 200
+
+// These are returned when interpolation, enabled via SetInterpolation,
+// fails: ErrInterpolationDepthExceeded wrapped in a ParseError, and
+// ErrInterpolationCycle wrapped in an InterpolationCycleError.
+var (
+	ErrInterpolationDepthExceeded = errors.New("interpolation depth exceeded")
+	ErrInterpolationCycle         = errors.New("interpolation cycle")
+)
+
+// An InterpolationCycleError reports the chain of keys that led back to a
+// key already being expanded.
+type InterpolationCycleError struct {
+	Chain []string
+}
+
+func (e *InterpolationCycleError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInterpolationCycle, strings.Join(e.Chain, " -> "))
+}
+
+func (e *InterpolationCycleError) Unwrap() error {
+	return ErrInterpolationCycle
+}
+
+// interpolateAll expands every %(key)s reference in sections in place.
+// Lookups are resolved against a snapshot of the unexpanded values so that
+// the order in which keys happen to be visited cannot change the result.
+func (r *Reader) interpolateAll(sections map[string]map[string]string) error {
+	raw := make(map[string]map[string]string, len(sections))
+	for section, options := range sections {
+		copied := make(map[string]string, len(options))
+		for key, value := range options {
+			copied[key] = value
+		}
+		raw[section] = copied
+	}
+
+	for section, options := range sections {
+		for key := range options {
+			expanded, err := r.expand(raw[section][key], section, raw, r.maxDepth, []string{key})
+			if err != nil {
+				return err
+			}
+			options[key] = expanded
+		}
+	}
+	return nil
+}
+
+// lookup resolves key against the current section, then the "default"
+// section, then the vars supplied via SetVars.
+func (r *Reader) lookup(key, section string, sections map[string]map[string]string) (string, bool) {
+	if value, ok := sections[section][key]; ok {
+		return value, true
+	}
+	if section != "default" {
+		if value, ok := sections["default"][key]; ok {
+			return value, true
+		}
+	}
+	value, ok := r.vars[key]
+	return value, ok
+}
+
+// expand replaces every %(key)s occurrence in value, recursively expanding
+// the looked-up value. chain holds the keys already being expanded along
+// the current path, used to detect cycles.
+func (r *Reader) expand(value, section string, sections map[string]map[string]string, depth int, chain []string) (string, error) {
+	if depth <= 0 {
+		return "", r.error(ErrInterpolationDepthExceeded)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		if value[i] != '%' {
+			b.WriteByte(value[i])
+			i++
+			continue
+		}
+		if i+1 < len(value) && value[i+1] == '%' {
+			b.WriteByte('%')
+			i += 2
+			continue
+		}
+		if i+1 >= len(value) || value[i+1] != '(' {
+			b.WriteByte(value[i])
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(value[i+2:], ')')
+		if end == -1 || i+2+end+1 >= len(value) || value[i+2+end+1] != 's' {
+			b.WriteByte(value[i])
+			i++
+			continue
+		}
+
+		key := value[i+2 : i+2+end]
+		for _, seen := range chain {
+			if seen == key {
+				return "", &InterpolationCycleError{Chain: append(append([]string{}, chain...), key)}
+			}
+		}
+
+		raw, ok := r.lookup(key, section, sections)
+		if !ok {
+			return "", fmt.Errorf("config: unknown interpolation key %q", key)
+		}
+		sub, err := r.expand(raw, section, sections, depth-1, append(chain, key))
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(sub)
+		i += 2 + end + 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestInterpolationBasic(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("[server]\nhost = example.com\nurl = http://%(host)s/path\n")
+	reader := NewReader(m)
+	reader.SetInterpolation(true)
+	sections, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "http://example.com/path"
+	if got := sections["server"]["url"]; got != want {
+		t.Errorf("url = %q, want %q", got, want)
+	}
+}
+
+func TestInterpolationForwardReference(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("[server]\nurl = http://%(host)s/path\nhost = example.com\n")
+	reader := NewReader(m)
+	reader.SetInterpolation(true)
+	sections, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "http://example.com/path"
+	if got := sections["server"]["url"]; got != want {
+		t.Errorf("url = %q, want %q", got, want)
+	}
+}
+
+func TestInterpolationFallsBackToDefaultSection(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("host = example.com\n[server]\nurl = http://%(host)s/path\n")
+	reader := NewReader(m)
+	reader.SetInterpolation(true)
+	sections, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "http://example.com/path"
+	if got := sections["server"]["url"]; got != want {
+		t.Errorf("url = %q, want %q", got, want)
+	}
+}
+
+func TestInterpolationFallsBackToVars(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("[server]\nurl = http://%(host)s/path\n")
+	reader := NewReader(m)
+	reader.SetInterpolation(true)
+	reader.SetVars(map[string]string{"host": "vars.example.com"})
+	sections, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "http://vars.example.com/path"
+	if got := sections["server"]["url"]; got != want {
+		t.Errorf("url = %q, want %q", got, want)
+	}
+}
+
+func TestInterpolationLiteralPercent(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("[server]\nprogress = 50%%\n")
+	reader := NewReader(m)
+	reader.SetInterpolation(true)
+	sections, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "50%"
+	if got := sections["server"]["progress"]; got != want {
+		t.Errorf("progress = %q, want %q", got, want)
+	}
+}
+
+func TestInterpolationCycle(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("[server]\na = %(b)s\nb = %(a)s\n")
+	reader := NewReader(m)
+	reader.SetInterpolation(true)
+	_, err := reader.ReadAll()
+	if err == nil {
+		t.Fatal("expected an interpolation cycle error")
+	}
+	if _, ok := err.(*InterpolationCycleError); !ok {
+		t.Errorf("expected *InterpolationCycleError, got %T: %v", err, err)
+	}
+}
+
+func TestInterpolationDepthExceeded(t *testing.T) {
+	m := new(bytes.Buffer)
+	fmt.Fprintln(m, "[server]")
+	for i := 0; i < 250; i++ {
+		fmt.Fprintf(m, "k%d = %%(k%d)s\n", i, i+1)
+	}
+	fmt.Fprintln(m, "k250 = end")
+	reader := NewReader(m)
+	reader.SetInterpolation(true)
+	_, err := reader.ReadAll()
+	if err == nil {
+		t.Fatal("expected a depth exceeded error")
+	}
+	pe, ok := err.(*ParseError)
+	if !ok || pe.Err != ErrInterpolationDepthExceeded {
+		t.Errorf("expected a ParseError wrapping ErrInterpolationDepthExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Load reads and merges one or more configuration sources into a single
+// File. Each source must be a string (a filename), a []byte, or an
+// io.Reader. Sources are merged left to right: a later source's section is
+// unioned with an earlier source's section of the same name, and a key
+// present in both overwrites the earlier source's value.
+//
+// A string source's directory is used to resolve any relative "!include"
+// directive it contains. A []byte or io.Reader source has no such
+// directory, so an "!include" directive found while reading it is reported
+// as ErrIncludeUnavailable.
+func Load(sources ...interface{}) (*File, error) {
+	f := &File{sections: make(map[string]*Section)}
+	for _, src := range sources {
+		raw, err := readSource(src)
+		if err != nil {
+			return nil, err
+		}
+		for name, options := range raw {
+			s, ok := f.sections[name]
+			if !ok {
+				s = newSection(name, nil)
+				f.sections[name] = s
+			}
+			for key, value := range options {
+				s.NewKey(key, value)
+			}
+		}
+	}
+	return f, nil
+}
+
+// readSource parses a single Load source into a flattened section tree.
+func readSource(src interface{}) (map[string]map[string]string, error) {
+	switch v := src.(type) {
+	case string:
+		file, err := os.Open(v)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		r := NewReader(file)
+		r.SetIncludeBaseDir(filepath.Dir(v))
+		if abs, err := filepath.Abs(v); err == nil {
+			r.includeStack = map[string]bool{abs: true}
+		}
+		return r.ReadAll()
+	case []byte:
+		return NewReader(bytes.NewReader(v)).ReadAll()
+	case io.Reader:
+		return NewReader(v).ReadAll()
+	default:
+		return nil, fmt.Errorf("config: unsupported source type %T", src)
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadMergesSourcesWithOverride(t *testing.T) {
+	base := "[server]\nhost = localhost\nport = 8080\n"
+	override := []byte("[server]\nport = 9090\n[logging]\nlevel = debug\n")
+
+	f, err := Load(strings.NewReader(base), override)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := f.Section("server")
+	if got := s.Key("host").String(); got != "localhost" {
+		t.Errorf("host = %q, want %q", got, "localhost")
+	}
+	if got := s.Key("port").MustInt(0); got != 9090 {
+		t.Errorf("port = %d, want %d", got, 9090)
+	}
+	if got := f.Section("logging").Key("level").String(); got != "debug" {
+		t.Errorf("level = %q, want %q", got, "debug")
+	}
+}
+
+func TestLoadRejectsUnsupportedSource(t *testing.T) {
+	if _, err := Load(42); err == nil {
+		t.Fatal("expected an error for an unsupported source type")
+	}
+}
+
+func TestLoadFileWithInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "extra.conf"), "[server]\nport = 8080\n")
+	writeFile(t, filepath.Join(dir, "main.conf"), "!include extra.conf\n[server]\nhost = localhost\n")
+
+	f, err := Load(filepath.Join(dir, "main.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := f.Section("server")
+	if got := s.Key("host").String(); got != "localhost" {
+		t.Errorf("host = %q, want %q", got, "localhost")
+	}
+	if got := s.Key("port").MustInt(0); got != 8080 {
+		t.Errorf("port = %d, want %d", got, 8080)
+	}
+}
+
+func TestLoadFileWithIncludeGlob(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "conf.d", "a.conf"), "[a]\nv = 1\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "b.conf"), "[b]\nv = 2\n")
+	writeFile(t, filepath.Join(dir, "main.conf"), "!include conf.d/*.conf\n")
+
+	f, err := Load(filepath.Join(dir, "main.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Section("a").Key("v").MustInt(0); got != 1 {
+		t.Errorf("a.v = %d, want 1", got)
+	}
+	if got := f.Section("b").Key("v").MustInt(0); got != 2 {
+		t.Errorf("b.v = %d, want 2", got)
+	}
+}
+
+func TestLoadFileIncludeMidSectionRestoresCurrentSection(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "inc.conf"), "[included]\nx = 1\n")
+	writeFile(t, filepath.Join(dir, "main.conf"), "[main]\na = 1\n!include inc.conf\nb = 2\n")
+
+	f, err := Load(filepath.Join(dir, "main.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	main := f.Section("main")
+	if got := main.Key("a").MustInt(0); got != 1 {
+		t.Errorf("main.a = %d, want 1", got)
+	}
+	if got := main.Key("b").MustInt(0); got != 2 {
+		t.Errorf("main.b = %d, want 2", got)
+	}
+	if f.Section("included").HasKey("b") {
+		t.Error("b should remain in [main], not leak into the included section")
+	}
+	if got := f.Section("included").Key("x").MustInt(0); got != 1 {
+		t.Errorf("included.x = %d, want 1", got)
+	}
+}
+
+func TestLoadFileIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.conf"), "!include b.conf\n")
+	writeFile(t, filepath.Join(dir, "b.conf"), "!include a.conf\n")
+
+	_, err := Load(filepath.Join(dir, "a.conf"))
+	if pe, ok := err.(*ParseError); !ok || pe.Err != ErrIncludeCycle {
+		t.Fatalf("expected a ParseError wrapping ErrIncludeCycle, got %v", err)
+	}
+}
+
+func TestLoadFileIncludeDiamondIsNotACycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "common.conf"), "[shared]\nv = 1\n")
+	writeFile(t, filepath.Join(dir, "a.conf"), "!include common.conf\n")
+	writeFile(t, filepath.Join(dir, "b.conf"), "!include common.conf\n")
+	writeFile(t, filepath.Join(dir, "top.conf"), "!include a.conf\n!include b.conf\n")
+
+	f, err := Load(filepath.Join(dir, "top.conf"))
+	if err != nil {
+		t.Fatalf("unexpected error for a diamond include: %v", err)
+	}
+	if got := f.Section("shared").Key("v").MustInt(0); got != 1 {
+		t.Errorf("shared.v = %d, want 1", got)
+	}
+}
+
+func TestLoadByteSourceRejectsInclude(t *testing.T) {
+	_, err := Load([]byte("!include anything.conf\n"))
+	if pe, ok := err.(*ParseError); !ok || pe.Err != ErrIncludeUnavailable {
+		t.Fatalf("expected a ParseError wrapping ErrIncludeUnavailable, got %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
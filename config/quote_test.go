@@ -0,0 +1,157 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestParseQuotedValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		value string
+	}{
+		{"double quotes", `"hello world"`, "hello world"},
+		{"single quotes", `'hello world'`, "hello world"},
+		{"leading and trailing space", `"  spaced  "`, "  spaced  "},
+		{"embedded hash", `"not # a comment"`, "not # a comment"},
+		{"embedded semicolon", `"not ; a comment"`, "not ; a comment"},
+		{"escaped backslash", `"back\\slash"`, `back\slash`},
+		{"escaped quote", `"quo\"te"`, `quo"te`},
+		{"escaped newline and tab", `"a\nb\tc"`, "a\nb\tc"},
+		{"escaped backspace", `"a\bb"`, "a\bb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(bytes.Buffer)
+			fmt.Fprintf(m, "key = %s\n", tt.raw)
+			reader := NewReader(m)
+			sections, err := reader.ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sections["default"]["key"]; got != tt.value {
+				t.Errorf("key = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestParseQuotedValueNoSpaceAfterDelimiter(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString(`key="hello world"` + "\n")
+	reader := NewReader(m)
+	sections, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "hello world"
+	if got := sections["default"]["key"]; got != want {
+		t.Errorf("key = %q, want %q", got, want)
+	}
+}
+
+func TestParseQuotedValueLineContinuation(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("key = \"line one\\\nline two\"\n")
+	reader := NewReader(m)
+	sections, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "line oneline two"
+	if got := sections["default"]["key"]; got != want {
+		t.Errorf("key = %q, want %q", got, want)
+	}
+}
+
+func TestParseUnterminatedQuote(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString(`key = "unterminated`)
+	reader := NewReader(m)
+	_, err := reader.ReadAll()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if pe, ok := err.(*ParseError); !ok || pe.Err != ErrUnterminatedQuote {
+		t.Errorf("expected ParseError wrapping ErrUnterminatedQuote, got %v", err)
+	}
+}
+
+func TestParseInvalidEscape(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString(`key = "bad \q escape"`)
+	reader := NewReader(m)
+	_, err := reader.ReadAll()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if pe, ok := err.(*ParseError); !ok || pe.Err != ErrInvalidEscape {
+		t.Errorf("expected ParseError wrapping ErrInvalidEscape, got %v", err)
+	}
+}
+
+func TestParseOptionEscapedCommentChars(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString(`key = value \# still value \; more` + "\n")
+	reader := NewReader(m)
+	sections, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "value # still value ; more"
+	if got := sections["default"]["key"]; got != want {
+		t.Errorf("key = %q, want %q", got, want)
+	}
+}
+
+func TestParseOptionCommentAfterOptionalSpace(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"hash", "key = #comment\n"},
+		{"semicolon", "key = ;comment\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(bytes.Buffer)
+			m.WriteString(tt.input)
+			reader := NewReader(m)
+			sections, err := reader.ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sections["default"]["key"]; got != "" {
+				t.Errorf("key = %q, want %q", got, "")
+			}
+		})
+	}
+}
+
+func TestWriteAllRoundTripWithSpecialCharacters(t *testing.T) {
+	sections := map[string]map[string]string{
+		"default": {
+			"hash":       "has # hash",
+			"leading":    " leading space",
+			"newline":    "line1\nline2",
+			"quoted":     `"hi"`,
+			"apostrophe": `'hi'`,
+		},
+	}
+	buf := new(bytes.Buffer)
+	if err := NewWriter(buf).WriteAll(sections); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range sections["default"] {
+		if got["default"][key] != want {
+			t.Errorf("round trip %s = %q, want %q", key, got["default"][key], want)
+		}
+	}
+}
@@ -15,6 +15,18 @@
 // An options consists of a name and a value separated with ':' or '=' characters.
 // Leading and trailing spaces will be trimmed from options names. There might be
 // options without a value.
+//
+// A Reader may optionally perform Python-ConfigParser-style interpolation on
+// values; see SetInterpolation.
+//
+// A section header may additionally carry a quoted subsection, gcfg/git
+// style: [remote "origin"]. ReadAll flattens such a section to the name
+// "remote.origin"; ReadAllTree keeps the section and subsection separate.
+//
+// A line of the form "!include path/glob" is expanded in place: the
+// referenced file(s), resolved with filepath.Glob relative to the
+// including file's directory, are parsed as if their contents appeared at
+// that point in the stream. See SetIncludeBaseDir and Load.
 
 package config
 
@@ -24,6 +36,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -43,15 +58,35 @@ func (e *ParseError) Error() string {
 var (
 	ErrParse              = errors.New("generic parse error")
 	ErrEmptySectionHeader = errors.New("empty section header")
+	ErrUnterminatedQuote  = errors.New("unterminated quote")
+	ErrInvalidEscape      = errors.New("invalid escape sequence")
+	ErrInvalidSubsection  = errors.New("invalid subsection")
+	ErrInvalidSectionName = errors.New("invalid section name")
+	ErrInvalidInclude     = errors.New("invalid include directive")
+	ErrIncludeUnavailable = errors.New("include directive requires a file-backed source")
+	ErrIncludeCycle       = errors.New("include cycle")
 )
 
+// sectionNamePattern is the character set allowed in a section name: a
+// plain header's name ([section]) or the outer name of a subsectioned
+// header, e.g. the "remote" in [remote "origin"].
+var sectionNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
 // A Reader reads sections of options from a configuration file.
 type Reader struct {
-	r              *bufio.Reader
-	field          bytes.Buffer
-	line           int
-	column         int
-	currentSection string
+	r                 *bufio.Reader
+	field             bytes.Buffer
+	line              int
+	column            int
+	currentSection    string
+	currentSubsection string
+
+	interpolate bool
+	vars        map[string]string
+	maxDepth    int
+
+	baseDir      string
+	includeStack map[string]bool
 }
 
 // NewReader returns a new Reader that reads from r.
@@ -59,9 +94,42 @@ func NewReader(r io.Reader) *Reader {
 	return &Reader{
 		r:              bufio.NewReader(r),
 		currentSection: "default",
+		maxDepth:       defaultMaxInterpolationDepth,
 	}
 }
 
+// SetInterpolation enables or disables Python-ConfigParser-style value
+// interpolation: an occurrence of %(key)s in a value is replaced with the
+// value of key, looked up first in the current section, then in the
+// "default" section, then in the vars supplied via SetVars. A literal '%'
+// is written as '%%'. Interpolation is applied once the whole file has been
+// parsed, so forward references are allowed.
+func (r *Reader) SetInterpolation(enabled bool) {
+	r.interpolate = enabled
+}
+
+// SetVars supplies a fallback map consulted by interpolation after the
+// current and "default" sections.
+func (r *Reader) SetVars(vars map[string]string) {
+	r.vars = vars
+}
+
+// SetMaxInterpolationDepth overrides the default recursion limit
+// (defaultMaxInterpolationDepth) applied while expanding interpolated
+// values.
+func (r *Reader) SetMaxInterpolationDepth(depth int) {
+	r.maxDepth = depth
+}
+
+// SetIncludeBaseDir sets the directory against which a relative
+// "!include" path is resolved. It is set automatically by Load for
+// file-backed sources; a Reader constructed directly over a []byte or
+// io.Reader has no base directory, so an "!include" directive found while
+// reading it is reported as ErrIncludeUnavailable.
+func (r *Reader) SetIncludeBaseDir(dir string) {
+	r.baseDir = dir
+}
+
 // error creates a new ParseError based on err.
 func (r *Reader) error(err error) error {
 	return &ParseError{
@@ -104,8 +172,43 @@ func (r *Reader) unreadRune() {
 // A successful call returns err == nil, not err == EOF. Because ReadAll is
 // defined to read until EOF, it does not treat end of file as an error to be
 // reported.
+//
+// A subsectioned header such as [remote "origin"] is flattened to the
+// section name "remote.origin"; use ReadAllTree to keep the section and
+// subsection separate.
 func (r *Reader) ReadAll() (sections map[string]map[string]string, err error) {
-	sections = make(map[string]map[string]string)
+	tree, err := r.readTree()
+	return flattenTree(tree), err
+}
+
+// ReadAllTree reads all the sections from r like ReadAll, but keeps
+// subsectioned headers such as [remote "origin"] separate instead of
+// flattening them: the outer key is the section ("remote"), the middle key
+// is the subsection ("origin", or "" for a header with none), and the inner
+// map holds that (sub)section's options.
+func (r *Reader) ReadAllTree() (tree map[string]map[string]map[string]string, err error) {
+	return r.readTree()
+}
+
+func (r *Reader) readTree() (tree map[string]map[string]map[string]string, err error) {
+	tree = make(map[string]map[string]map[string]string)
+	if err := r.readLines(tree); err != nil {
+		return tree, err
+	}
+	if r.interpolate {
+		flat := flattenTree(tree)
+		if err := r.interpolateAll(flat); err != nil {
+			return tree, err
+		}
+	}
+	return tree, nil
+}
+
+// readLines reads lines into tree until EOF, handling section headers,
+// options and "!include" directives. It is also used, recursively, to
+// splice an included file's lines into the same tree in place of its
+// "!include" directive; see includeFile.
+func (r *Reader) readLines(tree map[string]map[string]map[string]string) error {
 	for {
 		r.line++
 		r.column = 0
@@ -113,42 +216,161 @@ func (r *Reader) ReadAll() (sections map[string]map[string]string, err error) {
 
 		switch {
 		case err == io.EOF:
-			return sections, nil
+			return nil
 		case err != nil:
-			return sections, err
+			return err
 		case strings.ContainsRune("#;", r1):
 			err = r.skip('\n')
 			if err != nil && err != io.EOF {
-				return sections, err
+				return err
+			}
+		case r1 == '!':
+			if err := r.parseInclude(tree); err != nil {
+				return err
 			}
 		case r1 == '[':
-			section, err := r.parseHeader()
+			section, subsection, _, err := r.parseHeader()
 			if err != nil {
-				return sections, err
-			}
-			if _, ok := sections[section]; !ok {
-				sections[section] = make(map[string]string)
+				return err
 			}
+			ensureSection(tree, section, subsection)
 			r.currentSection = section
+			r.currentSubsection = subsection
 		default:
 			r.unreadRune()
 			key, value, err := r.parseOption()
 			if err != nil {
-				return sections, err
+				return err
 			}
 			key = strings.TrimSpace(key)
 
 			if len(key) != 0 {
-				if r.currentSection == "default" {
-					if _, ok := sections["default"]; !ok {
-						sections["default"] = make(map[string]string)
-					}
+				if r.currentSection == "default" && r.currentSubsection == "" {
+					ensureSection(tree, "default", "")
 				}
-				sections[r.currentSection][key] = value
+				tree[r.currentSection][r.currentSubsection][key] = value
 			}
 		}
 	}
-	panic("unreachable")
+}
+
+// parseInclude reads the remainder of an "!include path/glob" line and
+// expands it.
+func (r *Reader) parseInclude(tree map[string]map[string]map[string]string) error {
+	r.field.Reset()
+	for {
+		r1, err := r.readRune()
+		switch {
+		case err == io.EOF || r1 == '\n':
+			return r.expandInclude(strings.TrimSpace(r.field.String()), tree)
+		case err != nil:
+			return err
+		default:
+			r.field.WriteRune(r1)
+		}
+	}
+}
+
+// expandInclude resolves directive (the text following "!") as an include
+// pattern and splices every matching file into tree in place.
+func (r *Reader) expandInclude(directive string, tree map[string]map[string]map[string]string) error {
+	const prefix = "include "
+	if !strings.HasPrefix(directive, prefix) {
+		return r.error(ErrInvalidInclude)
+	}
+	pattern := strings.TrimSpace(directive[len(prefix):])
+	if pattern == "" {
+		return r.error(ErrInvalidInclude)
+	}
+	if r.baseDir == "" {
+		return r.error(ErrIncludeUnavailable)
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(r.baseDir, pattern)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return r.error(err)
+	}
+	for _, path := range matches {
+		if err := r.includeFile(path, tree); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includeFile parses path, splicing its sections and options into tree as
+// if they appeared in place of the "!include" directive that named it. The
+// includer's current section/subsection is restored once path is fully
+// read, so a section header inside the included file does not leak into
+// whatever follows the directive back in the includer.
+//
+// r.includeStack holds the paths currently being read, from the outermost
+// file down to path's immediate includer, so that a file that includes
+// itself (directly or transitively) is caught as ErrIncludeCycle. A path
+// is pushed before it is read and popped once it is done, so two sibling
+// "!include" directives naming the same file (a diamond, not a cycle) are
+// both allowed.
+func (r *Reader) includeFile(path string, tree map[string]map[string]map[string]string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return r.error(err)
+	}
+	if r.includeStack[abs] {
+		return r.error(ErrIncludeCycle)
+	}
+
+	f, err := os.Open(abs)
+	if err != nil {
+		return r.error(err)
+	}
+	defer f.Close()
+
+	if r.includeStack == nil {
+		r.includeStack = make(map[string]bool)
+	}
+	r.includeStack[abs] = true
+	defer delete(r.includeStack, abs)
+
+	savedR, savedLine, savedColumn, savedBaseDir := r.r, r.line, r.column, r.baseDir
+	savedSection, savedSubsection := r.currentSection, r.currentSubsection
+	r.r = bufio.NewReader(f)
+	r.line, r.column = 0, 0
+	r.baseDir = filepath.Dir(abs)
+
+	err = r.readLines(tree)
+
+	r.r, r.line, r.column, r.baseDir = savedR, savedLine, savedColumn, savedBaseDir
+	r.currentSection, r.currentSubsection = savedSection, savedSubsection
+	return err
+}
+
+func ensureSection(tree map[string]map[string]map[string]string, section, subsection string) {
+	if _, ok := tree[section]; !ok {
+		tree[section] = make(map[string]map[string]string)
+	}
+	if _, ok := tree[section][subsection]; !ok {
+		tree[section][subsection] = make(map[string]string)
+	}
+}
+
+// flattenTree collapses a section tree into the map[string]map[string]string
+// shape returned by ReadAll, joining a subsection onto its section name with
+// a dot.
+func flattenTree(tree map[string]map[string]map[string]string) map[string]map[string]string {
+	flat := make(map[string]map[string]string)
+	for section, subsections := range tree {
+		for subsection, options := range subsections {
+			name := section
+			if subsection != "" {
+				name = section + "." + subsection
+			}
+			flat[name] = options
+		}
+	}
+	return flat
 }
 
 // skip reads runes up to and including the rune delim or until error.
@@ -162,34 +384,125 @@ func (r *Reader) skip(delim rune) error {
 			return nil
 		}
 	}
-	panic("unreachable")
 }
 
-func (r *Reader) parseHeader() (section string, err error) {
+// skipOptionalSpace consumes a single space rune if the next rune read is
+// one, so that "key = value" and "key=value" parse the same way, and reports
+// whether it did. Anything else is left unread for the caller, instead of
+// being hunted for as skip(' ') would do, which would otherwise swallow the
+// rest of the line (including a quote that should open a quoted value) while
+// looking for a space that was never there.
+func (r *Reader) skipOptionalSpace() (bool, error) {
+	r1, err := r.readRune()
+	if err != nil {
+		return false, err
+	}
+	if r1 != ' ' {
+		r.unreadRune()
+		return false, nil
+	}
+	return true, nil
+}
+
+// parseHeader reads a section header, which is either a plain name
+// ([section]) or a gcfg/git-style subsection ([section "sub"]). In both
+// forms, section is validated against sectionNamePattern; in the latter
+// form, subsection is additionally unescaped from the quotes. hasSubsection
+// reports which form was seen.
+func (r *Reader) parseHeader() (section, subsection string, hasSubsection bool, err error) {
 	r.field.Reset()
 	for {
 		r1, err := r.readRune()
 
 		switch {
 		case err == io.EOF || strings.ContainsRune("#;", r1):
-			return section, r.error(ErrParse)
+			return section, subsection, hasSubsection, r.error(ErrParse)
 		case err != nil:
-			return section, err
+			return section, subsection, hasSubsection, err
+		case r1 == '"':
+			name := strings.TrimRight(r.field.String(), " \t")
+			if !sectionNamePattern.MatchString(name) {
+				return section, subsection, hasSubsection, r.error(ErrInvalidSectionName)
+			}
+			sub, err := r.parseSubsectionName()
+			if err != nil {
+				return section, subsection, hasSubsection, err
+			}
+			section, subsection, hasSubsection = name, sub, true
+			return r.finishSubsectionHeader(section, subsection)
 		case r1 == ']':
 			section = r.field.String()
 			if len(section) == 0 {
-				return section, r.error(ErrEmptySectionHeader)
+				return section, subsection, hasSubsection, r.error(ErrEmptySectionHeader)
+			}
+			if !sectionNamePattern.MatchString(section) {
+				return section, subsection, hasSubsection, r.error(ErrInvalidSectionName)
 			}
 			err = r.skip('\n')
 			if err != nil && err != io.EOF {
-				return section, err
+				return section, subsection, hasSubsection, err
 			}
-			return section, nil
+			return section, subsection, hasSubsection, nil
 		default:
 			r.field.WriteRune(r1)
 		}
 	}
-	panic("unreachable")
+}
+
+// parseSubsectionName reads a quoted subsection name, given that its opening
+// quote has already been consumed. It supports the escape sequences \\ and
+// \" only.
+func (r *Reader) parseSubsectionName() (string, error) {
+	var name bytes.Buffer
+	for {
+		r1, err := r.readRune()
+		switch {
+		case err == io.EOF || r1 == '\n':
+			return name.String(), r.error(ErrInvalidSubsection)
+		case err != nil:
+			return name.String(), err
+		case r1 == '"':
+			return name.String(), nil
+		case r1 == '\\':
+			r2, err := r.readRune()
+			switch {
+			case err == io.EOF:
+				return name.String(), r.error(ErrInvalidSubsection)
+			case err != nil:
+				return name.String(), err
+			case r2 == '\\' || r2 == '"':
+				name.WriteRune(r2)
+			default:
+				return name.String(), r.error(ErrInvalidSubsection)
+			}
+		default:
+			name.WriteRune(r1)
+		}
+	}
+}
+
+// finishSubsectionHeader consumes the ']' (and any whitespace leading up to
+// it) that closes a subsectioned header.
+func (r *Reader) finishSubsectionHeader(section, subsection string) (string, string, bool, error) {
+	for {
+		r1, err := r.readRune()
+		switch {
+		case err == io.EOF:
+			return section, subsection, true, r.error(ErrInvalidSubsection)
+		case err != nil:
+			return section, subsection, true, err
+		case r1 == ' ' || r1 == '\t':
+			continue
+		case r1 == ']':
+			err = r.skip('\n')
+			if err != nil && err != io.EOF {
+				return section, subsection, true, err
+			}
+			return section, subsection, true, nil
+		default:
+			return section, subsection, true, r.error(ErrInvalidSubsection)
+		}
+	}
 }
 
 func (r *Reader) parseOption() (key string, value string, err error) {
@@ -207,25 +520,103 @@ func (r *Reader) parseOption() (key string, value string, err error) {
 			return key, value, nil
 		case err != nil:
 			return key, value, err
+		case foundDelim && r.field.Len() == 0 && (r1 == '"' || r1 == '\''):
+			value, err = r.parseQuotedValue(r1)
+			if err != nil {
+				return key, value, err
+			}
+			err = r.skip('\n')
+			if err != nil && err != io.EOF {
+				return key, value, err
+			}
+			return key, value, nil
+		case r1 == '\\':
+			r2, err2 := r.readRune()
+			switch {
+			case err2 != nil && err2 != io.EOF:
+				return key, value, err2
+			case strings.ContainsRune("#;", r2):
+				r.field.WriteRune(r2)
+				lastRune = r2
+			default:
+				r.field.WriteRune(r1)
+				lastRune = r1
+				if err2 == nil {
+					r.unreadRune()
+				}
+			}
 		case (lastRune == 0 || lastRune == ' ') && strings.ContainsRune("#;", r1):
 			value = r.field.String()
 			err = r.skip('\n')
 			if err != nil && err != io.EOF {
 				return key, value, err
 			}
-			return key, value[:len(value)-1], nil
+			// value ends in the trailing space that triggered this case,
+			// except when the space was the one skipOptionalSpace consumed
+			// right after the delimiter, which never made it into field.
+			if len(value) > 0 {
+				value = value[:len(value)-1]
+			}
+			return key, value, nil
 		case !foundDelim && strings.ContainsRune("=:", r1):
 			key = r.field.String()
 			foundDelim = true
 			r.field.Reset()
-			err = r.skip(' ')
+			skipped, err := r.skipOptionalSpace()
 			if err != nil && err != io.EOF {
 				return key, value, err
 			}
+			lastRune = r1
+			if skipped {
+				lastRune = ' '
+			}
 		default:
 			r.field.WriteRune(r1)
 			lastRune = r1
 		}
 	}
-	panic("unreachable")
+}
+
+// parseQuotedValue reads a quoted value's contents, given that its opening
+// quote rune has already been consumed. It supports the escape sequences
+// \\, \", \', \n, \t, \b, and a backslash immediately followed by a newline
+// to continue the value on the next line.
+func (r *Reader) parseQuotedValue(quote rune) (string, error) {
+	var value bytes.Buffer
+	for {
+		r1, err := r.readRune()
+		switch {
+		case err == io.EOF:
+			return value.String(), r.error(ErrUnterminatedQuote)
+		case err != nil:
+			return value.String(), err
+		case r1 == quote:
+			return value.String(), nil
+		case r1 == '\n':
+			return value.String(), r.error(ErrUnterminatedQuote)
+		case r1 == '\\':
+			r2, err := r.readRune()
+			switch {
+			case err == io.EOF:
+				return value.String(), r.error(ErrUnterminatedQuote)
+			case err != nil:
+				return value.String(), err
+			case r2 == '\n':
+				r.line++
+				r.column = 0
+			case r2 == '\\', r2 == '"', r2 == '\'':
+				value.WriteRune(r2)
+			case r2 == 'n':
+				value.WriteByte('\n')
+			case r2 == 't':
+				value.WriteByte('\t')
+			case r2 == 'b':
+				value.WriteByte('\b')
+			default:
+				return value.String(), r.error(ErrInvalidEscape)
+			}
+		default:
+			value.WriteRune(r1)
+		}
+	}
 }
@@ -7,7 +7,7 @@ import (
 )
 
 func TestParseValidSectionHeader(t *testing.T) {
-	const name = "Some section"
+	const name = "Some-section"
 	m := new(bytes.Buffer)
 	m.WriteString(fmt.Sprintf("[%s]\n", name))
 	reader := NewReader(m)
@@ -25,31 +25,31 @@ func TestParseValidSectionHeader(t *testing.T) {
 
 func TestParseInvalidSectionHeader1(t *testing.T) {
 	m := new(bytes.Buffer)
-	m.WriteString("[Some section")
+	m.WriteString("[Some-section")
 	reader := NewReader(m)
 	_, err := reader.ReadAll()
-	if err.(*ParseError).Err != ErrInvalidSectionHeader {
-		t.Errorf("ReadAll should return %q error, returned %q", ErrInvalidSectionHeader, err)
+	if err.(*ParseError).Err != ErrParse {
+		t.Errorf("ReadAll should return %q error, returned %q", ErrParse, err)
 	}
 }
 
 func TestParseInvalidSectionHeader2(t *testing.T) {
 	m := new(bytes.Buffer)
-	m.WriteString("[Some section[\n")
+	m.WriteString("[Some-section[\n")
 	reader := NewReader(m)
 	_, err := reader.ReadAll()
-	if err.(*ParseError).Err != ErrInvalidSectionHeader {
-		t.Errorf("ReadAll should return %q error, returned %q", ErrInvalidSectionHeader, err)
+	if err.(*ParseError).Err != ErrParse {
+		t.Errorf("ReadAll should return %q error, returned %q", ErrParse, err)
 	}
 }
 
 func TestParseInvalidSectionHeader3(t *testing.T) {
 	m := new(bytes.Buffer)
-	m.WriteString("[Some section]\n[Oops\noption = value")
+	m.WriteString("[Some-section]\n[Oops\noption = value")
 	reader := NewReader(m)
 	_, err := reader.ReadAll()
-	if err.(*ParseError).Err != ErrInvalidSectionHeader {
-		t.Errorf("ReadAll should return %q error, returned %q", ErrInvalidSectionHeader, err)
+	if err.(*ParseError).Err != ErrParse {
+		t.Errorf("ReadAll should return %q error, returned %q", ErrParse, err)
 	}
 }
 
@@ -58,14 +58,14 @@ func TestParseEmptySectionHeader(t *testing.T) {
 	m.WriteString("[]")
 	reader := NewReader(m)
 	_, err := reader.ReadAll()
-	if err.(*ParseError).Err != ErrInvalidSectionHeader {
-		t.Errorf("ReadAll should return %q error, returned %q", ErrInvalidSectionHeader, err)
+	if err.(*ParseError).Err != ErrEmptySectionHeader {
+		t.Errorf("ReadAll should return %q error, returned %q", ErrEmptySectionHeader, err)
 	}
 }
 
 func TestParseEmptyLines(t *testing.T) {
-	const section1 = "Section 1"
-	const section2 = "Section 2"
+	const section1 = "Section-1"
+	const section2 = "Section-2"
 	m := new(bytes.Buffer)
 	m.WriteString(fmt.Sprintf("\n[%s]\n\n[%s]", section1, section2))
 	reader := NewReader(m)
@@ -85,8 +85,8 @@ func TestParseEmptyLines(t *testing.T) {
 }
 
 func TestParseCommentLines(t *testing.T) {
-	const section1 = "Section 1"
-	const section2 = "Section 2"
+	const section1 = "Section-1"
+	const section2 = "Section-2"
 	m := new(bytes.Buffer)
 	m.WriteString(fmt.Sprintf("\n[%s]\n#[Comment section]\n\t#[Another comment section]\n[%s]", section1, section2))
 	reader := NewReader(m)
@@ -131,7 +131,7 @@ func TestDefaultParseOption(t *testing.T) {
 }
 
 func TestParseOption1(t *testing.T) {
-	const name = "Some section"
+	const name = "Some-section"
 	const key = "SomeOption"
 	const value = "z = x + y"
 	m := new(bytes.Buffer)
@@ -157,7 +157,7 @@ func TestParseOption1(t *testing.T) {
 }
 
 func TestParseOption2(t *testing.T) {
-	const name = "Some section"
+	const name = "Some-section"
 	const key = "SomeOption"
 	const value = "z = x + y"
 	m := new(bytes.Buffer)
@@ -183,7 +183,7 @@ func TestParseOption2(t *testing.T) {
 }
 
 func TestParseOption3(t *testing.T) {
-	const name = "Some section"
+	const name = "Some-section"
 	const key = "SomeOption"
 	const value = "z = x + y"
 	m := new(bytes.Buffer)
@@ -209,7 +209,7 @@ func TestParseOption3(t *testing.T) {
 }
 
 func TestParseOptionWithComment(t *testing.T) {
-	const name = "Some section"
+	const name = "Some-section"
 	const key = "SomeOption"
 	const value = "z = x + y"
 	m := new(bytes.Buffer)
@@ -235,7 +235,7 @@ func TestParseOptionWithComment(t *testing.T) {
 }
 
 func TestParseOptionWithoutAComment(t *testing.T) {
-	const name = "Some section"
+	const name = "Some-section"
 	const key = "SomeOption"
 	const value = "z = x + y#Not a comment"
 	m := new(bytes.Buffer)
@@ -0,0 +1,346 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// BoolStrings maps the recognized string spellings of a boolean value to the
+// value they represent. It is consulted by every boolean conversion in this
+// package and may be extended or replaced by callers that need to recognize
+// additional spellings.
+var BoolStrings = map[string]bool{
+	"1":     true,
+	"t":     true,
+	"true":  true,
+	"y":     true,
+	"yes":   true,
+	"on":    true,
+	"0":     false,
+	"f":     false,
+	"false": false,
+	"n":     false,
+	"no":    false,
+	"off":   false,
+}
+
+// A MappingError describes a single field that could not be converted while
+// mapping between a struct and INI data.
+type MappingError struct {
+	Section string
+	Key     string
+	Err     error
+}
+
+func (e *MappingError) Error() string {
+	return fmt.Sprintf("%s.%s: %s", e.Section, e.Key, e.Err)
+}
+
+// MappingErrors aggregates every MappingError encountered by MapTo, so a
+// caller can report all of them instead of stopping at the first.
+type MappingErrors []*MappingError
+
+func (e MappingErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, me := range e {
+		parts[i] = me.Error()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// fieldTag is the parsed form of an `ini:"..."` struct tag.
+type fieldTag struct {
+	name      string
+	skip      bool
+	omitempty bool
+	isDefault bool
+	layout    string
+	delim     string
+}
+
+func parseFieldTag(field reflect.StructField) fieldTag {
+	ft := fieldTag{delim: ","}
+	tag := field.Tag.Get("ini")
+	if tag == "-" {
+		ft.skip = true
+		return ft
+	}
+	parts := strings.Split(tag, ",")
+	ft.name = parts[0]
+	for _, opt := range parts[1:] {
+		switch {
+		case opt == "omitempty":
+			ft.omitempty = true
+		case opt == "default":
+			ft.isDefault = true
+		case strings.HasPrefix(opt, "layout="):
+			ft.layout = strings.TrimPrefix(opt, "layout=")
+		case strings.HasPrefix(opt, "delim="):
+			ft.delim = strings.TrimPrefix(opt, "delim=")
+		}
+	}
+	return ft
+}
+
+// MapTo reads INI data from r into the struct pointed to by v. v must be a
+// pointer to a struct whose fields are themselves structs, one per section;
+// the section's fields are in turn populated from that section's options.
+//
+// Field and section names are derived from the Go field name (converted to
+// snake_case) unless overridden with an `ini:"name"` tag; `ini:"-"` skips a
+// field. A field tagged `ini:",default"` is populated from the implicit
+// "default" section regardless of its name.
+//
+// Every field that fails to convert is recorded rather than aborting the
+// rest of the mapping; if any field failed, MapTo returns a MappingErrors.
+func MapTo(v interface{}, r io.Reader) error {
+	sections, err := NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: MapTo requires a pointer to a struct, got %T", v)
+	}
+	structVal := rv.Elem()
+	structType := structVal.Type()
+
+	var errs MappingErrors
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		ft := parseFieldTag(field)
+		fv := structVal.Field(i)
+		if ft.skip || !fv.CanSet() {
+			continue
+		}
+		if fv.Kind() != reflect.Struct {
+			errs = append(errs, &MappingError{Key: field.Name, Err: fmt.Errorf("field must be a struct, is %s", fv.Kind())})
+			continue
+		}
+
+		sectionName := sectionNameFor(field.Name, ft)
+		mapSection(sectionName, fv, sections[sectionName], &errs)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func sectionNameFor(fieldName string, ft fieldTag) string {
+	if ft.isDefault {
+		return "default"
+	}
+	if ft.name != "" {
+		return ft.name
+	}
+	return toSnakeCase(fieldName)
+}
+
+func mapSection(sectionName string, structVal reflect.Value, options map[string]string, errs *MappingErrors) {
+	structType := structVal.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		ft := parseFieldTag(field)
+		fv := structVal.Field(i)
+		if ft.skip || !fv.CanSet() {
+			continue
+		}
+
+		keyName := ft.name
+		if keyName == "" {
+			keyName = toSnakeCase(field.Name)
+		}
+		raw, ok := options[keyName]
+		if !ok {
+			continue
+		}
+		if err := setFieldFromString(fv, raw, ft); err != nil {
+			*errs = append(*errs, &MappingError{Section: sectionName, Key: keyName, Err: err})
+		}
+	}
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+func setFieldFromString(fv reflect.Value, raw string, ft fieldTag) error {
+	switch fv.Type() {
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	case timeType:
+		layout := ft.layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, raw)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, ok := BoolStrings[strings.ToLower(raw)]
+		if !ok {
+			return fmt.Errorf("invalid bool value %q", raw)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Slice:
+		delim := ft.delim
+		if delim == "" {
+			delim = ","
+		}
+		parts := strings.Split(raw, delim)
+		slice := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := setFieldFromString(slice.Index(i), strings.TrimSpace(p), ft); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field kind %s", fv.Kind())
+	}
+	return nil
+}
+
+// ReflectFrom writes v, a struct or pointer to a struct laid out the same
+// way MapTo expects, to w as INI data.
+func ReflectFrom(v interface{}, w io.Writer) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("config: ReflectFrom requires a struct or pointer to struct, got %T", v)
+	}
+
+	structType := rv.Type()
+	sections := make(map[string]map[string]string)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		ft := parseFieldTag(field)
+		fv := rv.Field(i)
+		if ft.skip || fv.Kind() != reflect.Struct {
+			continue
+		}
+		sections[sectionNameFor(field.Name, ft)] = sectionToOptions(fv)
+	}
+	return NewWriter(w).WriteAll(sections)
+}
+
+func sectionToOptions(structVal reflect.Value) map[string]string {
+	structType := structVal.Type()
+	options := make(map[string]string)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		ft := parseFieldTag(field)
+		fv := structVal.Field(i)
+		if ft.skip || !fv.CanInterface() {
+			continue
+		}
+		if ft.omitempty && fv.IsZero() {
+			continue
+		}
+		keyName := ft.name
+		if keyName == "" {
+			keyName = toSnakeCase(field.Name)
+		}
+		options[keyName] = stringifyField(fv, ft)
+	}
+	return options
+}
+
+func stringifyField(fv reflect.Value, ft fieldTag) string {
+	switch fv.Type() {
+	case durationType:
+		return time.Duration(fv.Int()).String()
+	case timeType:
+		layout := ft.layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return fv.Interface().(time.Time).Format(layout)
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String()
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'g', -1, 64)
+	case reflect.Slice:
+		delim := ft.delim
+		if delim == "" {
+			delim = ","
+		}
+		parts := make([]string, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			parts[i] = stringifyField(fv.Index(i), ft)
+		}
+		return strings.Join(parts, delim)
+	default:
+		return fmt.Sprintf("%v", fv.Interface())
+	}
+}
+
+// toSnakeCase converts an exported Go identifier such as "MaxConns" or
+// "HTTPTimeout" to its snake_case form ("max_conns", "http_timeout").
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			startOfWord := i > 0 && !unicode.IsUpper(runes[i-1])
+			endOfAcronym := i > 0 && i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && !unicode.IsUpper(runes[i+1])
+			if i > 0 && (startOfWord || endOfAcronym) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
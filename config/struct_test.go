@@ -0,0 +1,158 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"MaxConns":    "max_conns",
+		"HTTPTimeout": "http_timeout",
+		"Name":        "name",
+		"ID":          "id",
+	}
+	for in, want := range tests {
+		if got := toSnakeCase(in); got != want {
+			t.Errorf("toSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+type serverSection struct {
+	Host    string
+	Port    int
+	Enabled bool
+	Timeout time.Duration
+	Tags    []string
+}
+
+type appConfig struct {
+	Server serverSection
+}
+
+func TestMapTo(t *testing.T) {
+	const data = `
+[server]
+host = localhost
+port = 8080
+enabled = yes
+timeout = 1500ms
+tags = a, b, c
+`
+	var cfg appConfig
+	if err := MapTo(&cfg, strings.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Host != "localhost" {
+		t.Errorf("Host = %q, want %q", cfg.Server.Host, "localhost")
+	}
+	if cfg.Server.Port != 8080 {
+		t.Errorf("Port = %d, want %d", cfg.Server.Port, 8080)
+	}
+	if !cfg.Server.Enabled {
+		t.Error("Enabled = false, want true")
+	}
+	if cfg.Server.Timeout != 1500*time.Millisecond {
+		t.Errorf("Timeout = %s, want %s", cfg.Server.Timeout, 1500*time.Millisecond)
+	}
+	if want := []string{"a", "b", "c"}; !equalStrings(cfg.Server.Tags, want) {
+		t.Errorf("Tags = %v, want %v", cfg.Server.Tags, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type taggedSection struct {
+	Addr string `ini:"address"`
+	skip string `ini:"-"`
+}
+
+type taggedConfig struct {
+	Net taggedSection `ini:"network"`
+}
+
+func TestMapToTagOverrides(t *testing.T) {
+	const data = "[network]\naddress = 127.0.0.1\n"
+	var cfg taggedConfig
+	if err := MapTo(&cfg, strings.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Net.Addr != "127.0.0.1" {
+		t.Errorf("Addr = %q, want %q", cfg.Net.Addr, "127.0.0.1")
+	}
+}
+
+type defaultSection struct {
+	Debug bool
+}
+
+type defaultConfig struct {
+	General defaultSection `ini:",default"`
+}
+
+func TestMapToDefaultSection(t *testing.T) {
+	const data = "debug = true\n"
+	var cfg defaultConfig
+	if err := MapTo(&cfg, strings.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.General.Debug {
+		t.Error("Debug = false, want true")
+	}
+}
+
+func TestMapToAggregatesErrors(t *testing.T) {
+	const data = "[server]\nport = not-a-number\n"
+	var cfg appConfig
+	err := MapTo(&cfg, strings.NewReader(data))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	errs, ok := err.(MappingErrors)
+	if !ok {
+		t.Fatalf("expected MappingErrors, got %T", err)
+	}
+	if len(errs) != 1 || errs[0].Key != "port" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestReflectFrom(t *testing.T) {
+	cfg := appConfig{Server: serverSection{
+		Host:    "localhost",
+		Port:    8080,
+		Enabled: true,
+		Timeout: 1500 * time.Millisecond,
+		Tags:    []string{"a", "b"},
+	}}
+
+	buf := new(bytes.Buffer)
+	if err := ReflectFrom(&cfg, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var out appConfig
+	if err := MapTo(&out, strings.NewReader(buf.String())); err != nil {
+		t.Fatal(err)
+	}
+	if out.Server.Host != cfg.Server.Host ||
+		out.Server.Port != cfg.Server.Port ||
+		out.Server.Enabled != cfg.Server.Enabled ||
+		out.Server.Timeout != cfg.Server.Timeout ||
+		!equalStrings(out.Server.Tags, cfg.Server.Tags) {
+		t.Errorf("round trip = %+v, want %+v", out, cfg)
+	}
+}
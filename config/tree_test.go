@@ -0,0 +1,129 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadAllFlattensSubsection(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("[remote \"origin\"]\nurl = git@example.com:repo.git\n")
+	reader := NewReader(m)
+	sections, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "git@example.com:repo.git"
+	if got := sections["remote.origin"]["url"]; got != want {
+		t.Errorf(`sections["remote.origin"]["url"] = %q, want %q`, got, want)
+	}
+}
+
+func TestReadAllTree(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("[remote \"origin\"]\nurl = a\n[remote \"upstream\"]\nurl = b\n[core]\nbare = false\n")
+	reader := NewReader(m)
+	tree, err := reader.ReadAllTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tree["remote"]["origin"]["url"]; got != "a" {
+		t.Errorf(`tree["remote"]["origin"]["url"] = %q, want "a"`, got)
+	}
+	if got := tree["remote"]["upstream"]["url"]; got != "b" {
+		t.Errorf(`tree["remote"]["upstream"]["url"] = %q, want "b"`, got)
+	}
+	if got := tree["core"][""]["bare"]; got != "false" {
+		t.Errorf(`tree["core"][""]["bare"] = %q, want "false"`, got)
+	}
+}
+
+func TestReadAllTreeBareSectionHasEmptySubsection(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("[core]\nbare = true\n")
+	reader := NewReader(m)
+	tree, err := reader.ReadAllTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree["core"]) != 1 {
+		t.Fatalf("expected one subsection entry, got %d", len(tree["core"]))
+	}
+	if _, ok := tree["core"][""]; !ok {
+		t.Error(`expected tree["core"][""] to exist`)
+	}
+}
+
+func TestSubsectionNameEscapes(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString(`[remote "with \"quote\" and \\slash"]` + "\nurl = x\n")
+	reader := NewReader(m)
+	tree, err := reader.ReadAllTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `with "quote" and \slash`
+	if _, ok := tree["remote"][want]; !ok {
+		t.Errorf("expected subsection %q, got %v", want, tree["remote"])
+	}
+}
+
+func TestInvalidSubsectionUnterminatedQuote(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString(`[remote "origin]` + "\nurl = x\n")
+	reader := NewReader(m)
+	_, err := reader.ReadAllTree()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if pe, ok := err.(*ParseError); !ok || pe.Err != ErrInvalidSubsection {
+		t.Errorf("expected ParseError wrapping ErrInvalidSubsection, got %v", err)
+	}
+}
+
+func TestInvalidSectionNameWithSubsection(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString(`["has space" "sub"]` + "\nurl = x\n")
+	reader := NewReader(m)
+	_, err := reader.ReadAllTree()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if pe, ok := err.(*ParseError); !ok || pe.Err != ErrInvalidSectionName {
+		t.Errorf("expected ParseError wrapping ErrInvalidSectionName, got %v", err)
+	}
+}
+
+// TestInvalidSectionNameCharsWithSubsection exercises the outer name
+// actually containing characters outside sectionNamePattern (as opposed to
+// TestInvalidSectionNameWithSubsection above, where the leading quote
+// leaves the outer name empty and it fails sectionNamePattern for that
+// reason alone).
+func TestInvalidSectionNameCharsWithSubsection(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString(`[my section "origin"]` + "\nurl = x\n")
+	reader := NewReader(m)
+	_, err := reader.ReadAllTree()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if pe, ok := err.(*ParseError); !ok || pe.Err != ErrInvalidSectionName {
+		t.Errorf("expected ParseError wrapping ErrInvalidSectionName, got %v", err)
+	}
+}
+
+// TestInvalidPlainHeaderName confirms that sectionNamePattern is enforced
+// on a plain header's name too, not just the outer name of a subsectioned
+// header.
+func TestInvalidPlainHeaderName(t *testing.T) {
+	m := new(bytes.Buffer)
+	m.WriteString("[Some section]\nkey = value\n")
+	reader := NewReader(m)
+	_, err := reader.ReadAll()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if pe, ok := err.(*ParseError); !ok || pe.Err != ErrInvalidSectionName {
+		t.Errorf("expected ParseError wrapping ErrInvalidSectionName, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Writer serializes sections of options back into INI format.
+//
+// The output is meant to be read back by a Reader: values that would be
+// misread (leading/trailing whitespace, or values containing '#', ';' or a
+// newline) are quoted and escaped so that ReadAll reproduces them exactly.
+type Writer struct {
+	w                 io.Writer
+	delimiter         rune
+	omitDefaultHeader bool
+}
+
+// A WriterOption configures a Writer returned by NewWriter.
+type WriterOption func(*Writer)
+
+// Delimiter sets the character written between a key and its value.
+// The default is '='.
+func Delimiter(d rune) WriterOption {
+	return func(w *Writer) {
+		w.delimiter = d
+	}
+}
+
+// OmitDefaultHeader causes the synthetic "default" section to be written
+// without a "[default]" header, matching how options appearing before any
+// section header are read back by a Reader.
+func OmitDefaultHeader() WriterOption {
+	return func(w *Writer) {
+		w.omitDefaultHeader = true
+	}
+}
+
+// NewWriter returns a new Writer that writes to w.
+func NewWriter(w io.Writer, opts ...WriterOption) *Writer {
+	writer := &Writer{
+		w:         w,
+		delimiter: '=',
+	}
+	for _, opt := range opts {
+		opt(writer)
+	}
+	return writer
+}
+
+// WriteAll writes every section in sections. Sections and their options are
+// written in sorted order so that the output is stable across calls. The
+// "default" section, if present, is always written first.
+func (w *Writer) WriteAll(sections map[string]map[string]string) error {
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		if name != "default" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if options, ok := sections["default"]; ok {
+		if !w.omitDefaultHeader {
+			if err := w.WriteSection("default"); err != nil {
+				return err
+			}
+		}
+		if err := w.writeOptions(options); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		if err := w.WriteSection(name); err != nil {
+			return err
+		}
+		if err := w.writeOptions(sections[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) writeOptions(options map[string]string) error {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := w.WriteOption(key, options[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSection writes a section header for name. It returns
+// ErrInvalidSectionName if name is not in the set a Reader can parse back
+// out of an unquoted header; see sectionNamePattern.
+func (w *Writer) WriteSection(name string) error {
+	if !sectionNamePattern.MatchString(name) {
+		return ErrInvalidSectionName
+	}
+	_, err := fmt.Fprintf(w.w, "[%s]\n", name)
+	return err
+}
+
+// WriteOption writes a single key/value pair, quoting and escaping value if
+// necessary.
+func (w *Writer) WriteOption(key, value string) error {
+	if needsQuoting(value) {
+		value = quoteValue(value)
+	}
+	_, err := fmt.Fprintf(w.w, "%s %c %s\n", key, w.delimiter, value)
+	return err
+}
+
+// needsQuoting reports whether value must be quoted for a Reader to parse it
+// back to the same string: leading/trailing whitespace, an embedded
+// comment character or newline, would otherwise be trimmed or truncate the
+// value; and a leading quote character would otherwise be misread by a
+// Reader as opening a quoted value itself, in which case it must instead be
+// quoted to be read back literally.
+func needsQuoting(value string) bool {
+	if value == "" {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(value)
+	last, _ := utf8.DecodeLastRuneInString(value)
+	if unicode.IsSpace(first) || unicode.IsSpace(last) || first == '"' || first == '\'' {
+		return true
+	}
+	return strings.ContainsAny(value, "#;\n")
+}
+
+// quoteValue wraps value in double quotes, backslash-escaping characters
+// that would otherwise end the quote or be misread.
+func quoteValue(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteSection(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	if err := w.WriteSection("some-section"); err != nil {
+		t.Fatal(err)
+	}
+	const want = "[some-section]\n"
+	if buf.String() != want {
+		t.Errorf("WriteSection wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteSectionInvalidName(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	err := w.WriteSection("Some section")
+	if err != ErrInvalidSectionName {
+		t.Errorf("WriteSection(%q) = %v, want ErrInvalidSectionName", "Some section", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("WriteSection should not write anything on error, wrote %q", buf.String())
+	}
+}
+
+func TestWriteSectionRoundTrip(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	if err := w.WriteSection("some-section"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteOption("key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["some-section"]["key"] != "value" {
+		t.Errorf("round trip [some-section] key = %q, want %q", got["some-section"]["key"], "value")
+	}
+}
+
+func TestWriteOption(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	if err := w.WriteOption("key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	const want = "key = value\n"
+	if buf.String() != want {
+		t.Errorf("WriteOption wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteOptionDelimiter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf, Delimiter(':'))
+	if err := w.WriteOption("key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	const want = "key : value\n"
+	if buf.String() != want {
+		t.Errorf("WriteOption wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteOptionQuotesWhitespaceAndComments(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{" leading", `key = " leading"` + "\n"},
+		{"trailing ", `key = "trailing "` + "\n"},
+		{"has # hash", `key = "has # hash"` + "\n"},
+		{"has ; semicolon", `key = "has ; semicolon"` + "\n"},
+		{"plain value", "key = plain value\n"},
+	}
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		w := NewWriter(buf)
+		if err := w.WriteOption("key", tt.value); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("WriteOption(%q) wrote %q, want %q", tt.value, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestWriteAllRoundTrip(t *testing.T) {
+	sections := map[string]map[string]string{
+		"default": {"first": "1"},
+		"a":       {"x": "1", "y": "2"},
+		"b":       {"z": "3"},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewWriter(buf).WriteAll(sections); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(sections) {
+		t.Fatalf("round trip produced %d sections, want %d", len(got), len(sections))
+	}
+	for name, options := range sections {
+		for key, value := range options {
+			if got[name][key] != value {
+				t.Errorf("round trip [%s] %s = %q, want %q", name, key, got[name][key], value)
+			}
+		}
+	}
+}
+
+func TestWriteAllOmitDefaultHeader(t *testing.T) {
+	sections := map[string]map[string]string{
+		"default": {"key": "value"},
+	}
+	buf := new(bytes.Buffer)
+	if err := NewWriter(buf, OmitDefaultHeader()).WriteAll(sections); err != nil {
+		t.Fatal(err)
+	}
+	const want = "key = value\n"
+	if buf.String() != want {
+		t.Errorf("WriteAll wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteAllStableOrder(t *testing.T) {
+	sections := map[string]map[string]string{
+		"b": {"y": "2", "x": "1"},
+		"a": {"k": "v"},
+	}
+	first := new(bytes.Buffer)
+	second := new(bytes.Buffer)
+	if err := NewWriter(first).WriteAll(sections); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewWriter(second).WriteAll(sections); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("WriteAll output is not stable across calls:\n%s\nvs\n%s", first.String(), second.String())
+	}
+}